@@ -0,0 +1,42 @@
+package batch
+
+import "context"
+
+// WithContext creates a new batch group and an associated Context derived from ctx.
+// It mirrors errgroup.WithContext: the derived Context is canceled the first time a
+// function passed to GoContext returns an error, or the callback returns an error.
+// limit has the same meaning as in New.
+func WithContext[T any](ctx context.Context, limit int, cb func(v []T) error) (*Group[T], context.Context) {
+	g := New(limit, cb)
+	g.baseCtx = ctx
+	g.deriveCtx()
+	return &g, g.ctx
+}
+
+// deriveCtx (re)derives g.ctx/g.cancel from g.baseCtx. It is also called by Reset so a
+// Group created with WithContext gets a fresh, non-canceled Context after a successful
+// Reset, instead of being stuck with the previous round's canceled one.
+func (g *Group[T]) deriveCtx() {
+	g.ctx, g.cancel = context.WithCancel(g.baseCtx)
+}
+
+// Context returns the Context currently associated with g. It is only meaningful for a
+// Group created with WithContext, and must be called again after Reset: Reset derives a
+// fresh, non-canceled Context, so a Context obtained before Reset is no longer the one
+// passed to GoContext functions, and stays canceled if the previous round failed.
+func (g *Group[T]) Context() context.Context {
+	return g.ctx
+}
+
+// GoContext behaves like Go but calls f with the Context returned by WithContext, so f
+// can observe cancellation and return promptly instead of continuing to run after the
+// batch has already failed.
+// GoContext returns ErrNotWithContext if g was not created with WithContext.
+func (g *Group[T]) GoContext(f func(ctx context.Context) ([]T, error)) error {
+	if g.ctx == nil {
+		return ErrNotWithContext
+	}
+	return g.Go(func() ([]T, error) {
+		return f(g.ctx)
+	})
+}
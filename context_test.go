@@ -0,0 +1,58 @@
+package batch_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jybp/batch/v2"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithContext_CanceledOnError(t *testing.T) {
+	bg, ctx := batch.WithContext(context.Background(), 3, func(res []int) error {
+		return nil
+	})
+	bg.GoContext(func(ctx context.Context) ([]int, error) {
+		return nil, fmt.Errorf("boom")
+	})
+	bg.GoContext(func(ctx context.Context) ([]int, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	bg.GoContext(func(ctx context.Context) ([]int, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	require.Error(t, bg.Wait())
+	require.Error(t, ctx.Err())
+}
+
+func TestWithContext_CanceledOnCallbackError(t *testing.T) {
+	bg, ctx := batch.WithContext(context.Background(), 1, func(res []int) error {
+		return fmt.Errorf("callback failed")
+	})
+	bg.GoContext(func(ctx context.Context) ([]int, error) {
+		return []int{0}, nil
+	})
+	bg.GoContext(func(ctx context.Context) ([]int, error) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Error("context was not canceled")
+		}
+		return []int{0}, nil
+	})
+	require.Error(t, bg.Wait())
+	require.Error(t, ctx.Err())
+}
+
+func TestGoContext_ErrNotWithContextWithoutWithContext(t *testing.T) {
+	bg := batch.New(1, func(res []int) error { return nil })
+	err := bg.GoContext(func(ctx context.Context) ([]int, error) {
+		return []int{0}, nil
+	})
+	require.ErrorIs(t, err, batch.ErrNotWithContext)
+}
@@ -0,0 +1,54 @@
+package batch
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed is returned by Go or Wait when called on a Group that has already been
+	// finalized by a prior call to Wait. Call Reset to reuse the Group.
+	ErrClosed = errors.New("batch: group closed")
+
+	// ErrCallbackFailed is wrapped by the error recorded when cb fails, so that errors.Is can
+	// tell a callback failure apart from an error returned by a Go function. That error
+	// carries the text of cb's error, but does not wrap cb's error itself.
+	ErrCallbackFailed = errors.New("batch: callback failed")
+
+	// ErrNotWithContext is returned by GoContext when called on a Group not created via
+	// WithContext.
+	ErrNotWithContext = errors.New("batch: GoContext called on a Group not created with WithContext")
+)
+
+// Reset clears a Group's error and closed state so it can be reused for another round
+// of work with the same cb and limit. Reset MUST only be called after Wait has returned.
+func (g *Group[T]) Reset() {
+	g.wg.Wait()
+	if g.stop != nil {
+		// The Group was created with NewWithFlush. Wait already closed g.stop, but
+		// flushLoop only observes that asynchronously; wait for it to actually exit
+		// before restarting it below, otherwise the old goroutine can keep running
+		// against the new stop/flushSignal channels assigned next.
+		g.flushWG.Wait()
+	}
+	g.mu.Lock()
+	g.err = nil
+	g.res = nil
+	g.pendingSince = time.Time{}
+	g.mu.Unlock()
+	g.calls = 0
+	g.closed = false
+	if g.maxWait > 0 {
+		g.stop = make(chan struct{})
+		g.stopOnce = sync.Once{}
+		g.flushSignal = make(chan struct{}, 1)
+		g.flushWG.Add(1)
+		go g.flushLoop()
+	}
+	if g.baseCtx != nil {
+		// The Group was created with WithContext: a previous error permanently canceled
+		// g.ctx, so derive a fresh one. Callers must fetch it again via Context().
+		g.deriveCtx()
+	}
+}
@@ -0,0 +1,86 @@
+package batch_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jybp/batch/v2"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_ErrClosed_AfterWait(t *testing.T) {
+	bg := batch.New(1, func(res []int) error { return nil })
+	bg.Go(func() ([]int, error) { return []int{1}, nil })
+	require.NoError(t, bg.Wait())
+
+	require.ErrorIs(t, bg.Go(func() ([]int, error) { return []int{2}, nil }), batch.ErrClosed)
+	require.ErrorIs(t, bg.Wait(), batch.ErrClosed)
+}
+
+func TestGroup_ErrCallbackFailed(t *testing.T) {
+	bg := batch.New(1, func(res []int) error { return fmt.Errorf("boom") })
+	bg.Go(func() ([]int, error) { return []int{1}, nil })
+	err := bg.Wait()
+	require.ErrorIs(t, err, batch.ErrCallbackFailed)
+}
+
+func TestGroup_Reset(t *testing.T) {
+	c := 0
+	bg := batch.New(1, func(res []int) error {
+		c++
+		return nil
+	})
+	bg.Go(func() ([]int, error) { return []int{1}, nil })
+	require.NoError(t, bg.Wait())
+
+	bg.Reset()
+	require.NoError(t, bg.Go(func() ([]int, error) { return []int{2}, nil }))
+	require.NoError(t, bg.Wait())
+	require.Equal(t, 2, c)
+}
+
+func TestGroup_Reset_RefreshesCanceledContext(t *testing.T) {
+	fail := true
+	bg, _ := batch.WithContext(context.Background(), 1, func(res []int) error {
+		if fail {
+			fail = false
+			return fmt.Errorf("boom")
+		}
+		return nil
+	})
+	bg.GoContext(func(ctx context.Context) ([]int, error) { return []int{1}, nil })
+	require.Error(t, bg.Wait())
+	require.Error(t, bg.Context().Err())
+
+	bg.Reset()
+	require.NoError(t, bg.Context().Err())
+
+	require.NoError(t, bg.GoContext(func(ctx context.Context) ([]int, error) {
+		require.NoError(t, ctx.Err())
+		return []int{2}, nil
+	}))
+	require.NoError(t, bg.Wait())
+}
+
+func TestGroup_Reset_WaitsForPreviousFlushLoopToExit(t *testing.T) {
+	var mu sync.Mutex
+	var flushes [][]int
+	bg := batch.NewWithFlush(10, time.Millisecond, func(res []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		flushes = append(flushes, append([]int{}, res...))
+		return nil
+	})
+	for round := 0; round < 20; round++ {
+		bg.Go(func() ([]int, error) { return []int{round}, nil })
+		require.NoError(t, bg.Wait())
+		bg.Reset()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, flushes, 20)
+}
@@ -0,0 +1,63 @@
+package batch
+
+import "time"
+
+// NewWithFlush creates a new batch group like New, but additionally flushes a partial
+// batch to cb once maxWait has elapsed since the first buffered result, even if limit
+// results have not accumulated yet. This matters when Go calls arrive in bursts separated
+// by idle periods: without NewWithFlush, the last partial batch only reaches cb when Wait
+// is called.
+//
+// Because of the time-triggered flush, cb may be called with fewer than limit results
+// even though more Go calls are still in flight; v still always satisfies
+// 0 < len(v) <= limit.
+func NewWithFlush[T any](limit int, maxWait time.Duration, cb func(v []T) error) *Group[T] {
+	g := New(limit, cb)
+	g.maxWait = maxWait
+	g.stop = make(chan struct{})
+	g.flushSignal = make(chan struct{}, 1)
+	g.flushWG.Add(1)
+	go g.flushLoop()
+	return &g
+}
+
+// flushLoop flushes a partial batch once maxWait has elapsed since the first buffered
+// result. While idle (no buffered result) it blocks instead of waking up on a fixed
+// cadence, and noteResultLocked wakes it as soon as a result arrives so it can size its
+// wait off pendingSince rather than a fixed period.
+func (g *Group[T]) flushLoop() {
+	defer g.flushWG.Done()
+	for {
+		g.mu.Lock()
+		// Once g.err is set, drain is permanently a no-op (drainLocked returns early
+		// without clearing g.res), so treat the batch as not pending: otherwise the
+		// wait<=0 branch below would call drain in a tight loop forever and never
+		// reach a select that can observe g.stop.
+		pending := len(g.res) > 0 && g.err == nil
+		wait := g.maxWait - time.Since(g.pendingSince)
+		g.mu.Unlock()
+
+		if !pending {
+			select {
+			case <-g.stop:
+				return
+			case <-g.flushSignal:
+			}
+			continue
+		}
+		if wait <= 0 {
+			g.drain()
+			continue
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-g.stop:
+			t.Stop()
+			return
+		case <-g.flushSignal:
+			t.Stop()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package batch_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jybp/batch/v2"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithFlush_FlushesPartialBatchAfterMaxWait(t *testing.T) {
+	var mu sync.Mutex
+	var flushes [][]int
+	bg := batch.NewWithFlush(10, time.Millisecond*20, func(res []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		flushes = append(flushes, append([]int{}, res...))
+		return nil
+	})
+	bg.Go(func() ([]int, error) { return []int{1}, nil })
+	bg.Go(func() ([]int, error) { return []int{2}, nil })
+
+	time.Sleep(time.Millisecond * 60)
+
+	mu.Lock()
+	require.NotEmpty(t, flushes)
+	require.ElementsMatch(t, []int{1, 2}, flushes[0])
+	mu.Unlock()
+
+	require.NoError(t, bg.Wait())
+}
+
+func TestNewWithFlush_SizeFlushStillWorks(t *testing.T) {
+	c := 0
+	bg := batch.NewWithFlush(2, time.Second, func(res []int) error {
+		c++
+		require.ElementsMatch(t, []int{0, 1}, res)
+		return nil
+	})
+	bg.Go(func() ([]int, error) { return []int{0}, nil })
+	bg.Go(func() ([]int, error) { return []int{1}, nil })
+	require.NoError(t, bg.Wait())
+	require.Equal(t, 1, c)
+}
+
+func TestNewWithFlush_BurstAfterFlushDoesNotWaitTwiceMaxWait(t *testing.T) {
+	var mu sync.Mutex
+	var flushedAt []time.Time
+	maxWait := time.Millisecond * 40
+	bg := batch.NewWithFlush(10, maxWait, func(res []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		flushedAt = append(flushedAt, time.Now())
+		return nil
+	})
+
+	flushCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(flushedAt)
+	}
+
+	bg.Go(func() ([]int, error) { return []int{0}, nil })
+	require.Eventually(t, func() bool { return flushCount() == 1 }, maxWait*10, time.Millisecond)
+
+	mu.Lock()
+	firstFlush := flushedAt[0]
+	mu.Unlock()
+
+	// Submit the second item right after the first flush, and make sure it doesn't wait
+	// for a second full maxWait on top of that: a fixed-cadence timer tied to the
+	// Group's creation (rather than this item's own arrival) would do exactly that.
+	bg.Go(func() ([]int, error) { return []int{1}, nil })
+	require.Eventually(t, func() bool { return flushCount() == 2 }, maxWait*10, time.Millisecond)
+	require.NoError(t, bg.Wait())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Less(t, flushedAt[1].Sub(firstFlush), maxWait*3/2)
+}
+
+func TestNewWithFlush_ResetDoesNotHangAfterGoError(t *testing.T) {
+	bg := batch.NewWithFlush(10, time.Millisecond*5, func(res []int) error {
+		return fmt.Errorf("boom")
+	})
+	bg.Go(func() ([]int, error) { return []int{1}, nil })
+	bg.Go(func() ([]int, error) { return nil, fmt.Errorf("early error") })
+
+	done := make(chan struct{})
+	go func() {
+		bg.Wait()
+		bg.Reset()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 2):
+		// Once err is set, drain permanently no-ops without clearing res, so a flushLoop
+		// that keeps treating a non-empty res as pending would spin forever and never
+		// reach the select that lets Reset's flushWG.Wait() return.
+		t.Fatal("Reset did not return: flushLoop likely livelocked")
+	}
+}
@@ -5,17 +5,42 @@
 package batch
 
 import (
+	"context"
+	"fmt"
 	"sync"
+	"time"
 )
 
 // A Group is a collection of goroutines working the same overall task batched together.
 type Group[T any] struct {
-	wg      sync.WaitGroup
-	cb      func([]T) error
-	errOnce sync.Once
-	err     error
-	res     chan T
-	calls   uint64
+	wg    sync.WaitGroup
+	cb    func([]T) error
+	limit int
+	calls uint64
+
+	// mu guards err, res, closed and every call to cb, so that the size-triggered drain
+	// in Go, the time-triggered drain started by NewWithFlush, and errors recorded by
+	// concurrently running goroutines never race with each other.
+	mu     sync.Mutex
+	err    error
+	res    []T
+	closed bool
+
+	// baseCtx, ctx and cancel are set by WithContext and used by GoContext and Reset.
+	baseCtx context.Context
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	// maxWait, stop, stopOnce and flushWG are set by NewWithFlush and used by the flush
+	// goroutine and Reset. pendingSince and flushSignal let the flush goroutine wait
+	// exactly until maxWait has elapsed since the first buffered result, instead of
+	// waking up on a fixed cadence.
+	maxWait      time.Duration
+	stop         chan struct{}
+	stopOnce     sync.Once
+	flushWG      sync.WaitGroup
+	pendingSince time.Time
+	flushSignal  chan struct{}
 }
 
 // New creates a new batch group with limit >= 1 number of concurrent goroutines.
@@ -29,36 +54,27 @@ func New[T any](limit int, cb func(v []T) error) Group[T] {
 		limit = 1
 	}
 	return Group[T]{
-		cb:  cb,
-		res: make(chan T, limit),
+		cb:    cb,
+		limit: limit,
 	}
 }
 
-// Go calls the given function in a new goroutine.
-// If the number of goroutines reached the limit, it first waits for all goroutines to finish
+// Go calls the given function in a new goroutine. f may return any number of results; they
+// are all added to the current batch and passed to the callback at most limit at a time.
+// If the number of Go calls reached the limit, it first waits for all goroutines to finish
 // and calls the callback function if no errors occured.
-// Go becomes a no-op if an error occured.
-func (g *Group[T]) Go(f func() (T, error)) {
-	if g.err != nil {
-		// An error occured in the previous batch.
-		// Turn Go into a no-op and let the caller call Wait.
-		return
+// Go becomes a no-op and returns the error if an error occured, or ErrClosed if the Group
+// was already finalized by a call to Wait.
+func (g *Group[T]) Go(f func() ([]T, error)) error {
+	if err := g.checkOpen(); err != nil {
+		return err
 	}
-	if g.calls > 0 && g.calls%uint64(cap(g.res)) == 0 {
+	if g.calls > 0 && g.calls%uint64(g.limit) == 0 {
 		g.wg.Wait()
-		res := []T{}
-		for len(g.res) > 0 {
-			res = append(res, <-g.res) // Drain the channel.
-		}
-		if g.err != nil {
-			// An error occured in the current batch.
-			// Turn Go into a no-op and let the caller call Wait.
-			return
-		}
-		if g.err = g.cb(res); g.err != nil {
-			// An error occured in the callback.
+		if err := g.drain(); err != nil {
+			// An error occured in the current batch or the callback.
 			// Turn Go into a no-op and let the caller call Wait.
-			return
+			return err
 		}
 	}
 	g.calls++
@@ -66,31 +82,109 @@ func (g *Group[T]) Go(f func() (T, error)) {
 	go func() {
 		defer g.wg.Done()
 		v, err := f()
+		g.mu.Lock()
+		defer g.mu.Unlock()
 		if err != nil {
-			g.errOnce.Do(func() {
-				g.err = err
-			})
+			g.setErrLocked(err)
 		}
-		g.res <- v
+		g.noteResultLocked(v)
 	}()
+	return nil
+}
+
+// checkOpen returns ErrClosed if the Group was finalized by Wait, or the first error seen
+// so far if any, turning the caller into a no-op.
+func (g *Group[T]) checkOpen() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return ErrClosed
+	}
+	return g.err
+}
+
+// setErrLocked records err as g.err if it is the first error seen, and, for groups created
+// with WithContext, cancels the associated Context. The caller must hold g.mu.
+func (g *Group[T]) setErrLocked(err error) {
+	if g.err == nil {
+		g.err = err
+		if g.cancel != nil {
+			g.cancel()
+		}
+	}
+}
+
+// noteResultLocked appends v to g.res and, for groups created with NewWithFlush, records
+// when the first result of the current batch arrived and wakes the flush goroutine so it
+// can size its timer off that instant instead of a fixed cadence. The caller must hold g.mu.
+func (g *Group[T]) noteResultLocked(v []T) {
+	if len(v) == 0 {
+		return
+	}
+	empty := len(g.res) == 0
+	g.res = append(g.res, v...)
+	if empty && g.flushSignal != nil {
+		g.pendingSince = time.Now()
+		select {
+		case g.flushSignal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// drain takes g.mu and calls drainLocked: if g.err is nil, whatever is currently buffered in
+// g.res is passed to cb; otherwise g.err is returned and g.res is left as it is.
+func (g *Group[T]) drain() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.drainLocked()
+}
+
+// drainLocked is the implementation of drain. The caller must hold g.mu.
+func (g *Group[T]) drainLocked() error {
+	if g.err != nil {
+		return g.err
+	}
+	if len(g.res) == 0 {
+		return nil
+	}
+	res := g.res
+	g.res = nil
+	for len(res) > 0 {
+		n := len(res)
+		if n > g.limit {
+			n = g.limit
+		}
+		if err := g.cb(res[:n:n]); err != nil {
+			err = fmt.Errorf("%w: %v", ErrCallbackFailed, err)
+			g.setErrLocked(err)
+			return err
+		}
+		res = res[n:]
+	}
+	return nil
 }
 
 // Wait waits for all leftover goroutines to finish and calls the callback function if
 // necessary.
 // Wait MUST be called after all Go calls to ensure there's no leftover goroutines.
-// The error returned will be the first error that occured.
+// The error returned will be the first error that occured, or ErrClosed if the Group was
+// already finalized by a previous call to Wait. Call Reset to reuse the Group afterwards.
 func (g *Group[T]) Wait() error {
-	g.wg.Wait()
-	res := []T{}
-	for len(g.res) > 0 {
-		res = append(res, <-g.res)
+	if err := g.checkOpen(); err == ErrClosed {
+		return err
 	}
-	if g.err != nil {
-		// return the first error if any.
-		return g.err
+	g.wg.Wait()
+	if g.stop != nil {
+		g.stopOnce.Do(func() { close(g.stop) })
 	}
-	if len(res) > 0 {
-		return g.cb(res)
+	g.mu.Lock()
+	err := g.drainLocked()
+	g.closed = true
+	gerr := g.err
+	g.mu.Unlock()
+	if err != nil {
+		return err
 	}
-	return nil
+	return gerr
 }
@@ -0,0 +1,54 @@
+package batch
+
+// Pipeline returns a new *Group[A] whose flushed batches are transformed by stage and
+// fed into next: whenever the returned group's callback would normally fire (Go reaching
+// limit, or Wait), stage is called with the flushed []A instead, and every B it returns
+// is submitted to next via Go.
+//
+// Multi-stage pipelines (fetch -> transform -> persist, ...) are built by chaining calls
+// to Pipeline back to front, each stage getting its own concurrency limit:
+//
+//	persist := batch.New(persistLimit, persistCb)
+//	transform := batch.Pipeline(transformLimit, transformStage, &persist)
+//	fetch := batch.Pipeline(fetchLimit, fetchStage, transform)
+//
+// Go's type system does not allow a single variadic constructor across stages of
+// different types, so Pipeline only connects one stage to the next; PipelineWait ties
+// the whole chain's shutdown together.
+func Pipeline[A, B any](limit int, stage func(a []A) ([]B, error), next *Group[B]) *Group[A] {
+	g := New(limit, func(as []A) error {
+		bs, err := stage(as)
+		if err != nil {
+			return err
+		}
+		// Submit one item per next.Go call, so next's batching (which counts Go calls,
+		// not items) still caps cb at next's own limit items, regardless of how many
+		// items stage produced for this single call.
+		for _, b := range bs {
+			b := b
+			if err := next.Go(func() ([]B, error) { return []B{b}, nil }); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return &g
+}
+
+// waiter is satisfied by every *Group[T], regardless of T.
+type waiter interface {
+	Wait() error
+}
+
+// PipelineWait waits on each stage in order, from the entry stage to the final one, and
+// returns the first error encountered. Waiting in order ensures a stage's leftover
+// partial batch is flushed into the next stage before that next stage is waited on.
+func PipelineWait(stages ...waiter) error {
+	var first error
+	for _, s := range stages {
+		if err := s.Wait(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
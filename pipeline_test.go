@@ -0,0 +1,87 @@
+package batch_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jybp/batch/v2"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipeline_TwoStages(t *testing.T) {
+	var mu sync.Mutex
+	var persisted []string
+
+	persist := batch.New(2, func(res []string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		persisted = append(persisted, res...)
+		return nil
+	})
+
+	fetch := batch.Pipeline(2, func(as []int) ([]string, error) {
+		bs := make([]string, len(as))
+		for i, a := range as {
+			bs[i] = fmt.Sprintf("v%d", a)
+		}
+		return bs, nil
+	}, &persist)
+
+	for i := 0; i < 5; i++ {
+		i := i
+		fetch.Go(func() ([]int, error) { return []int{i}, nil })
+	}
+
+	require.NoError(t, batch.PipelineWait(fetch, &persist))
+	mu.Lock()
+	defer mu.Unlock()
+	require.ElementsMatch(t, []string{"v0", "v1", "v2", "v3", "v4"}, persisted)
+}
+
+func TestPipeline_RespectsNextsLimit(t *testing.T) {
+	var mu sync.Mutex
+	maxSeen := 0
+
+	persist := batch.New(2, func(res []string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(res) > maxSeen {
+			maxSeen = len(res)
+		}
+		return nil
+	})
+
+	// A single fetch.Go call produces far more items than persist's limit; Pipeline
+	// must still submit them to persist one at a time so persist's own batching caps
+	// cb at its limit, regardless of how many items stage returned in one go.
+	fetch := batch.Pipeline(1, func(as []int) ([]string, error) {
+		bs := make([]string, 0, len(as)*10)
+		for _, a := range as {
+			for i := 0; i < 10; i++ {
+				bs = append(bs, fmt.Sprintf("v%d-%d", a, i))
+			}
+		}
+		return bs, nil
+	}, &persist)
+
+	fetch.Go(func() ([]int, error) { return []int{1}, nil })
+	require.NoError(t, batch.PipelineWait(fetch, &persist))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.LessOrEqual(t, maxSeen, 2)
+}
+
+func TestPipeline_PropagatesStageError(t *testing.T) {
+	persist := batch.New(2, func(res []string) error { return nil })
+	fetch := batch.Pipeline(2, func(as []int) ([]string, error) {
+		return nil, fmt.Errorf("transform failed")
+	}, &persist)
+
+	fetch.Go(func() ([]int, error) { return []int{1}, nil })
+	fetch.Go(func() ([]int, error) { return []int{2}, nil })
+
+	require.Error(t, batch.PipelineWait(fetch, &persist))
+}
@@ -0,0 +1,108 @@
+package batch
+
+import "sync"
+
+// A Pool runs a fixed number of worker goroutines that continuously pull jobs off an
+// internal queue, flushing completed results to cb every flushSize results or whatever
+// is left when Wait is called. Unlike Group, a slow job only occupies the worker running
+// it; it does not stall the workers processing other jobs, so throughput scales with the
+// slowest job rather than the slowest batch.
+type Pool[T any] struct {
+	jobs    chan func() (T, error)
+	cb      func([]T) error
+	flush   int
+	workers sync.WaitGroup
+	pending sync.WaitGroup
+
+	// mu guards res and err. res is a mutex-guarded slice, as in Group, so appending a
+	// result and draining are mutually exclusive and a drain can never absorb a result
+	// meant for the next batch.
+	mu  sync.Mutex
+	res []T
+	err error
+}
+
+// NewPool creates a Pool with workers >= 1 goroutines draining an internal job queue.
+// cb is called with up to flushSize results at a time, as soon as flushSize results have
+// accumulated, or with whatever is left over when Wait is called.
+func NewPool[T any](workers, flushSize int, cb func(v []T) error) *Pool[T] {
+	if workers <= 0 {
+		workers = 1
+	}
+	if flushSize <= 0 {
+		flushSize = 1
+	}
+	p := &Pool[T]{
+		jobs:  make(chan func() (T, error)),
+		cb:    cb,
+		flush: flushSize,
+	}
+	for i := 0; i < workers; i++ {
+		p.workers.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+func (p *Pool[T]) work() {
+	defer p.workers.Done()
+	for f := range p.jobs {
+		v, err := f()
+		p.mu.Lock()
+		if err != nil && p.err == nil {
+			p.err = err
+		}
+		p.res = append(p.res, v)
+		full := len(p.res) >= p.flush
+		if full {
+			p.drainLocked()
+		}
+		p.mu.Unlock()
+		p.pending.Done()
+	}
+}
+
+// Go submits f to the pool to be run by the next available worker.
+// Go becomes a no-op and returns the error if an error already occured.
+func (p *Pool[T]) Go(f func() (T, error)) error {
+	p.mu.Lock()
+	err := p.err
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	p.pending.Add(1)
+	p.jobs <- f
+	return nil
+}
+
+// drainLocked calls cb with whatever is currently buffered in res and empties res; once an
+// error has occured, the buffered results are discarded without calling cb. Callers must
+// hold p.mu.
+func (p *Pool[T]) drainLocked() {
+	if len(p.res) == 0 {
+		return
+	}
+	res := p.res
+	p.res = nil
+	if p.err != nil {
+		return
+	}
+	if err := p.cb(res); err != nil {
+		p.err = err
+	}
+}
+
+// Wait waits for all submitted jobs to finish, flushes any leftover results, and returns
+// the first error that occured. Wait MUST be called after all Go calls, and the Pool MUST
+// NOT be reused afterwards.
+func (p *Pool[T]) Wait() error {
+	p.pending.Wait()
+	close(p.jobs)
+	p.workers.Wait()
+	p.mu.Lock()
+	p.drainLocked()
+	err := p.err
+	p.mu.Unlock()
+	return err
+}
@@ -0,0 +1,116 @@
+package batch_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jybp/batch/v2"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool_FlushesBySize(t *testing.T) {
+	var mu sync.Mutex
+	var flushes [][]int
+	p := batch.NewPool(4, 3, func(res []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		cp := append([]int{}, res...)
+		flushes = append(flushes, cp)
+		return nil
+	})
+	for i := 0; i < 9; i++ {
+		i := i
+		p.Go(func() (int, error) {
+			return i, nil
+		})
+	}
+	require.NoError(t, p.Wait())
+	mu.Lock()
+	defer mu.Unlock()
+	total := 0
+	for _, f := range flushes {
+		require.Len(t, f, 3)
+		total += len(f)
+	}
+	require.Equal(t, 9, total)
+}
+
+func TestPool_SlowJobDoesNotStallOtherWorkers(t *testing.T) {
+	var mu sync.Mutex
+	var seen []int
+	p := batch.NewPool(3, 4, func(res []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, res...)
+		return nil
+	})
+	var fastDone int32
+	p.Go(func() (int, error) {
+		time.Sleep(time.Millisecond * 100)
+		if n := atomic.LoadInt32(&fastDone); n != 3 {
+			t.Errorf("only %d fast jobs finished while the slow job was running", n)
+		}
+		return 0, nil
+	})
+	for i := 1; i < 4; i++ {
+		i := i
+		p.Go(func() (int, error) {
+			atomic.AddInt32(&fastDone, 1)
+			return i, nil
+		})
+	}
+	require.NoError(t, p.Wait())
+	mu.Lock()
+	defer mu.Unlock()
+	require.ElementsMatch(t, []int{0, 1, 2, 3}, seen)
+}
+
+func TestPool_CallbackError(t *testing.T) {
+	p := batch.NewPool(2, 1, func(res []int) error {
+		return fmt.Errorf("boom")
+	})
+	p.Go(func() (int, error) { return 0, nil })
+	require.Error(t, p.Wait())
+}
+
+func TestPool_FlushNeverExceedsFlushSize(t *testing.T) {
+	var mu sync.Mutex
+	var flushes [][]int
+	const flushSize = 1
+	p := batch.NewPool(8, flushSize, func(res []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		cp := append([]int{}, res...)
+		flushes = append(flushes, cp)
+		return nil
+	})
+	for i := 0; i < 200; i++ {
+		i := i
+		p.Go(func() (int, error) { return i, nil })
+	}
+	require.NoError(t, p.Wait())
+	mu.Lock()
+	defer mu.Unlock()
+	total := 0
+	for _, f := range flushes {
+		require.LessOrEqual(t, len(f), flushSize)
+		total += len(f)
+	}
+	require.Equal(t, 200, total)
+}
+
+func TestPool_Go_ReturnsErrorAfterCallbackError(t *testing.T) {
+	p := batch.NewPool(1, 1, func(res []int) error {
+		return fmt.Errorf("boom")
+	})
+	require.NoError(t, p.Go(func() (int, error) { return 0, nil }))
+	// Give the worker time to run the job and the callback before submitting again, so
+	// Go observes the recorded error instead of racing with it.
+	time.Sleep(time.Millisecond * 50)
+	require.Error(t, p.Go(func() (int, error) { return 1, nil }))
+	require.Error(t, p.Wait())
+}
@@ -0,0 +1,43 @@
+package batch
+
+import (
+	"context"
+	"time"
+)
+
+// Timed wraps f so that it returns (zero value, context.DeadlineExceeded) if f does not
+// complete within d. f keeps running in the background after the timeout; its result,
+// if any, is discarded.
+func Timed[T any](d time.Duration, f func() (T, error)) func() (T, error) {
+	return func() (T, error) {
+		type result struct {
+			v   T
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			v, err := f()
+			done <- result{v, err}
+		}()
+		select {
+		case r := <-done:
+			return r.v, r.err
+		case <-time.After(d):
+			var zero T
+			return zero, context.DeadlineExceeded
+		}
+	}
+}
+
+// GoTimed calls Go with f wrapped by Timed: if f does not complete within d, the batch
+// records context.DeadlineExceeded as its error instead of waiting for f to return.
+func (g *Group[T]) GoTimed(d time.Duration, f func() (T, error)) error {
+	tf := Timed(d, f)
+	return g.Go(func() ([]T, error) {
+		v, err := tf()
+		if err != nil {
+			return nil, err
+		}
+		return []T{v}, nil
+	})
+}
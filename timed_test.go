@@ -0,0 +1,40 @@
+package batch_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jybp/batch/v2"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimed_DeadlineExceeded(t *testing.T) {
+	f := batch.Timed(time.Millisecond*10, func() (int, error) {
+		time.Sleep(time.Millisecond * 100)
+		return 1, nil
+	})
+	v, err := f()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 0, v)
+}
+
+func TestTimed_CompletesInTime(t *testing.T) {
+	f := batch.Timed(time.Millisecond*100, func() (int, error) {
+		return 1, nil
+	})
+	v, err := f()
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+}
+
+func TestGoTimed_PropagatesDeadlineExceeded(t *testing.T) {
+	bg := batch.New(3, func(res []int) error { return nil })
+	bg.GoTimed(time.Millisecond*10, func() (int, error) {
+		time.Sleep(time.Millisecond * 100)
+		return 1, nil
+	})
+	err := bg.Wait()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}